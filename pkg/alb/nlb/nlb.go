@@ -0,0 +1,91 @@
+// Package nlb gates the load-balancer-type: nlb annotation: it decides whether an Ingress is
+// asking for an NLB and rejects it up front when it asks for something an NLB can't do (too many
+// listeners, an ALB-only annotation). It doesn't provision NLBs itself; pkg/ingress fails closed
+// on one instead, rather than silently falling back to an ALB.
+package nlb
+
+import (
+	"fmt"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+	"k8s.io/client-go/tools/record"
+)
+
+// LoadBalancerType is the value of the load-balancer-type annotation.
+type LoadBalancerType string
+
+const (
+	// TypeALB is the default: an Application Load Balancer with host/path routed listener rules.
+	TypeALB LoadBalancerType = "alb"
+	// TypeNLB requests a Network Load Balancer. Nothing in this controller provisions one: an
+	// ALBIngress of this type fails its reconcile in pkg/ingress instead of building an ALB.
+	TypeNLB LoadBalancerType = "nlb"
+)
+
+// loadBalancerTypeAnnotation selects between an ALB (default) and an NLB for a given Ingress.
+const loadBalancerTypeAnnotation = "alb.ingress.kubernetes.io/load-balancer-type"
+
+// MaxListeners is the hard limit AWS imposes on the number of listeners an NLB may have.
+const MaxListeners = 50
+
+// unsupportedNLBAnnotations are meaningful for an ALB but have no NLB equivalent; an Ingress
+// requesting an NLB that also sets one of these is misconfigured.
+var unsupportedNLBAnnotations = []string{
+	"alb.ingress.kubernetes.io/waf-acl-id",
+	"alb.ingress.kubernetes.io/security-groups",
+}
+
+// TypeFor returns the load balancer type requested by ingResource, defaulting to TypeALB.
+func TypeFor(ingResource *extensions.Ingress) LoadBalancerType {
+	switch LoadBalancerType(ingResource.Annotations[loadBalancerTypeAnnotation]) {
+	case TypeNLB:
+		return TypeNLB
+	default:
+		return TypeALB
+	}
+}
+
+// Validate rejects annotation combinations and listener counts that don't make sense for an NLB,
+// recording a single event on ingResource that lists every problem found.
+func Validate(ingResource *extensions.Ingress, recorder record.EventRecorder) error {
+	if TypeFor(ingResource) != TypeNLB {
+		return nil
+	}
+
+	var problems []string
+	for _, annotation := range unsupportedNLBAnnotations {
+		if _, ok := ingResource.Annotations[annotation]; ok {
+			problems = append(problems, annotation)
+		}
+	}
+
+	// An NLB has no path-based routing, so every host/path rule the ALB path would otherwise
+	// collapse into one listener's rules needs its own listener here.
+	if listeners := listenerCount(ingResource); listeners > MaxListeners {
+		problems = append(problems, fmt.Sprintf("%d listeners requested, exceeds MaxListeners (%d)", listeners, MaxListeners))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	err := fmt.Errorf("invalid for load-balancer-type=nlb: %v", problems)
+	if recorder != nil {
+		recorder.Event(ingResource, "Warning", "ERROR", err.Error())
+	}
+	return err
+}
+
+// listenerCount returns the number of NLB listeners ingResource would require: one per host/path
+// rule, since an NLB forwards a whole listener straight to a target group rather than routing
+// within it the way an ALB's listener rules do.
+func listenerCount(ingResource *extensions.Ingress) int {
+	count := 0
+	for _, rule := range ingResource.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		count += len(rule.HTTP.Paths)
+	}
+	return count
+}
@@ -0,0 +1,57 @@
+package nlb
+
+import (
+	"testing"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+
+	"github.com/coreos/alb-ingress-controller/pkg/util/testutil"
+)
+
+func ingressWithAnnotations(annotations map[string]string) *extensions.Ingress {
+	return testutil.NewIngress("", annotations)
+}
+
+func TestTypeForDefaultsToALB(t *testing.T) {
+	if got := TypeFor(ingressWithAnnotations(nil)); got != TypeALB {
+		t.Errorf("TypeFor() with no annotation = %v, want %v", got, TypeALB)
+	}
+	if got := TypeFor(ingressWithAnnotations(map[string]string{loadBalancerTypeAnnotation: "bogus"})); got != TypeALB {
+		t.Errorf("TypeFor() with unrecognized value = %v, want %v", got, TypeALB)
+	}
+}
+
+func TestTypeForNLB(t *testing.T) {
+	ing := ingressWithAnnotations(map[string]string{loadBalancerTypeAnnotation: "nlb"})
+	if got := TypeFor(ing); got != TypeNLB {
+		t.Errorf("TypeFor() = %v, want %v", got, TypeNLB)
+	}
+}
+
+func TestValidateIgnoresALBIngresses(t *testing.T) {
+	ing := ingressWithAnnotations(map[string]string{"alb.ingress.kubernetes.io/waf-acl-id": "abc"})
+	if err := Validate(ing, nil); err != nil {
+		t.Errorf("Validate() on an ALB Ingress = %v, want nil", err)
+	}
+}
+
+func TestValidateRejectsUnsupportedAnnotation(t *testing.T) {
+	ing := ingressWithAnnotations(map[string]string{
+		loadBalancerTypeAnnotation:              "nlb",
+		"alb.ingress.kubernetes.io/waf-acl-id": "abc",
+	})
+	if err := Validate(ing, nil); err == nil {
+		t.Error("Validate() = nil, want an error for an unsupported NLB annotation")
+	}
+}
+
+func TestValidateRejectsTooManyListeners(t *testing.T) {
+	ing := ingressWithAnnotations(map[string]string{loadBalancerTypeAnnotation: "nlb"})
+	paths := make([]extensions.HTTPIngressPath, MaxListeners+1)
+	ing.Spec.Rules = []extensions.IngressRule{
+		{IngressRuleValue: extensions.IngressRuleValue{HTTP: &extensions.HTTPIngressRuleValue{Paths: paths}}},
+	}
+	if err := Validate(ing, nil); err == nil {
+		t.Error("Validate() = nil, want an error when listener count exceeds MaxListeners")
+	}
+}
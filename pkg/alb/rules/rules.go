@@ -0,0 +1,187 @@
+// Package rules reconciles the set of ALB listener rules required to satisfy
+// the host/path conditions described by an Ingress resource against the
+// rules that actually exist on a listener in AWS.
+package rules
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awselbv2 "github.com/aws/aws-sdk-go/service/elbv2"
+
+	"github.com/coreos/alb-ingress-controller/pkg/aws/elbv2"
+	"github.com/coreos/alb-ingress-controller/pkg/util/log"
+)
+
+var logger *log.Logger
+
+func init() {
+	logger = log.New("rules")
+}
+
+// Rule represents a single desired or existing ALB listener rule, materialized from one
+// host/path combination of an Ingress rule. Priority controls the order rules are evaluated.
+type Rule struct {
+	IngressRuleCondition *awselbv2.RuleCondition
+	Priority             int
+	TargetGroupArn       *string
+	CurrentRule          *awselbv2.Rule
+	DesiredRule          *awselbv2.Rule
+	Deleted              bool
+}
+
+// Rules is a list of Rule, ordered by ascending priority.
+type Rules []*Rule
+
+// NewRule returns a Rule for the given host, path and target group, assigning it the provided
+// priority. An empty path defaults to "/*", matching the Ingress spec's treatment of one.
+func NewRule(priority int, hostname, path string, targetGroupArn *string) *Rule {
+	if path == "" {
+		path = "/*"
+	}
+
+	var conditions []*awselbv2.RuleCondition
+	if hostname != "" {
+		conditions = append(conditions, &awselbv2.RuleCondition{
+			Field:  aws.String("host-header"),
+			Values: []*string{aws.String(hostname)},
+		})
+	}
+	conditions = append(conditions, &awselbv2.RuleCondition{
+		Field:  aws.String("path-pattern"),
+		Values: []*string{aws.String(path)},
+	})
+
+	return &Rule{
+		Priority:       priority,
+		TargetGroupArn: targetGroupArn,
+		DesiredRule: &awselbv2.Rule{
+			Priority:   aws.String(fmt.Sprintf("%v", priority)),
+			Conditions: conditions,
+			Actions: []*awselbv2.Action{
+				{
+					Type:           aws.String("forward"),
+					TargetGroupArn: targetGroupArn,
+				},
+			},
+		},
+	}
+}
+
+// Reconcile compares the desired Rules against the rules currently attached to listenerArn in AWS
+// and issues CreateRule/ModifyRule/DeleteRule calls to match. Rules are matched to their existing
+// counterpart by priority, the only stable identifier available before a rule exists.
+func (rs Rules) Reconcile(listenerArn *string) error {
+	current, err := describeRules(listenerArn)
+	if err != nil {
+		return fmt.Errorf("failed to describe rules for listener %s: %s", aws.StringValue(listenerArn), err.Error())
+	}
+
+	byPriority := make(map[string]*awselbv2.Rule)
+	for _, rule := range current {
+		// The default rule has no priority to diff against; it's managed
+		// implicitly by the listener's default action.
+		if aws.StringValue(rule.Priority) == "default" {
+			continue
+		}
+		byPriority[aws.StringValue(rule.Priority)] = rule
+	}
+
+	for _, r := range rs {
+		priority := aws.StringValue(r.DesiredRule.Priority)
+		existing, ok := byPriority[priority]
+		if !ok {
+			if err := r.create(listenerArn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		r.CurrentRule = existing
+		delete(byPriority, priority)
+
+		if r.needsModification() {
+			if err := r.modify(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Anything left in byPriority is no longer desired and must be removed.
+	for _, orphan := range byPriority {
+		if _, err := elbv2.ELBV2svc.DeleteRule(&awselbv2.DeleteRuleInput{
+			RuleArn: orphan.RuleArn,
+		}); err != nil {
+			return fmt.Errorf("failed to delete orphaned rule %s: %s", aws.StringValue(orphan.RuleArn), err.Error())
+		}
+		logger.Infof("Deleted orphaned rule %s", aws.StringValue(orphan.RuleArn))
+	}
+
+	return nil
+}
+
+func describeRules(listenerArn *string) ([]*awselbv2.Rule, error) {
+	resp, err := elbv2.ELBV2svc.DescribeRules(&awselbv2.DescribeRulesInput{
+		ListenerArn: listenerArn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+func (r *Rule) needsModification() bool {
+	if aws.StringValue(r.CurrentRule.Actions[0].TargetGroupArn) != aws.StringValue(r.TargetGroupArn) {
+		return true
+	}
+	return !conditionsEqual(r.CurrentRule.Conditions, r.DesiredRule.Conditions)
+}
+
+func (r *Rule) create(listenerArn *string) error {
+	resp, err := elbv2.ELBV2svc.CreateRule(&awselbv2.CreateRuleInput{
+		ListenerArn: listenerArn,
+		Priority:    aws.Int64(int64(r.Priority)),
+		Conditions:  r.DesiredRule.Conditions,
+		Actions:     r.DesiredRule.Actions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create rule: %s", err.Error())
+	}
+	r.CurrentRule = resp.Rules[0]
+	logger.Infof("Created rule %s at priority %v", aws.StringValue(r.CurrentRule.RuleArn), r.Priority)
+	return nil
+}
+
+func (r *Rule) modify() error {
+	resp, err := elbv2.ELBV2svc.ModifyRule(&awselbv2.ModifyRuleInput{
+		RuleArn:    r.CurrentRule.RuleArn,
+		Conditions: r.DesiredRule.Conditions,
+		Actions:    r.DesiredRule.Actions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to modify rule %s: %s", aws.StringValue(r.CurrentRule.RuleArn), err.Error())
+	}
+	r.CurrentRule = resp.Rules[0]
+	logger.Infof("Modified rule %s", aws.StringValue(r.CurrentRule.RuleArn))
+	return nil
+}
+
+func conditionsEqual(a, b []*awselbv2.RuleCondition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if aws.StringValue(a[i].Field) != aws.StringValue(b[i].Field) {
+			return false
+		}
+		if len(a[i].Values) != len(b[i].Values) {
+			return false
+		}
+		for j := range a[i].Values {
+			if aws.StringValue(a[i].Values[j]) != aws.StringValue(b[i].Values[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestNewRuleDefaultsEmptyPath(t *testing.T) {
+	r := NewRule(1, "example.com", "", aws.String("arn:tg"))
+	if got, want := aws.StringValue(r.DesiredRule.Conditions[1].Values[0]), "/*"; got != want {
+		t.Errorf("path condition = %v, want %v", got, want)
+	}
+}
+
+func TestNewRuleOmitsHostCondition(t *testing.T) {
+	r := NewRule(1, "", "/foo", aws.String("arn:tg"))
+	if len(r.DesiredRule.Conditions) != 1 {
+		t.Fatalf("expected only a path condition when hostname is empty, got %d conditions", len(r.DesiredRule.Conditions))
+	}
+	if got, want := aws.StringValue(r.DesiredRule.Conditions[0].Field), "path-pattern"; got != want {
+		t.Errorf("condition field = %v, want %v", got, want)
+	}
+}
+
+func TestConditionsEqual(t *testing.T) {
+	a := NewRule(1, "example.com", "/foo", aws.String("arn:tg")).DesiredRule.Conditions
+	b := NewRule(2, "example.com", "/foo", aws.String("arn:other-tg")).DesiredRule.Conditions
+	if !conditionsEqual(a, b) {
+		t.Error("expected conditions built from the same host/path to be equal regardless of target group")
+	}
+
+	c := NewRule(1, "example.com", "/bar", aws.String("arn:tg")).DesiredRule.Conditions
+	if conditionsEqual(a, c) {
+		t.Error("expected conditions with different paths to be unequal")
+	}
+}
@@ -0,0 +1,55 @@
+// Package ec2 wraps the AWS SDK's ec2 client with the operations the controller needs for
+// security groups: tagging the ones it creates, and finding them again by that tag.
+package ec2
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+
+	"github.com/coreos/alb-ingress-controller/pkg/aws/elbv2"
+)
+
+// EC2 wraps the ec2 SDK client, promoting its methods (DescribeSecurityGroups, CreateTags, etc.)
+// directly so callers can use it exactly like the raw client.
+type EC2 struct {
+	ec2iface.EC2API
+}
+
+// EC2svc is the EC2 instance used throughout the controller, set by NewEC2.
+var EC2svc *EC2
+
+// NewEC2 sets EC2svc to a new client built from sess.
+func NewEC2(sess *session.Session) {
+	EC2svc = &EC2{awsec2.New(sess)}
+}
+
+// TagSecurityGroup applies the same ownership/resource-type tags as elbv2.TagResource, so a
+// security group the controller created can be found (and torn down) the same way.
+func (e *EC2) TagSecurityGroup(groupID *string, clusterName, resourceType string) error {
+	_, err := e.CreateTags(&awsec2.CreateTagsInput{
+		Resources: []*string{groupID},
+		Tags: []*awsec2.Tag{
+			{Key: aws.String(elbv2.ClusterTagKey(clusterName)), Value: aws.String(elbv2.ClusterTagOwnedValue)},
+			{Key: aws.String(elbv2.ResourceTagKey), Value: aws.String(resourceType)},
+		},
+	})
+	return err
+}
+
+// GetClusterSecurityGroups returns every security group tagged as owned by clusterName.
+func (e *EC2) GetClusterSecurityGroups(clusterName *string) ([]*awsec2.SecurityGroup, error) {
+	resp, err := e.DescribeSecurityGroups(&awsec2.DescribeSecurityGroupsInput{
+		Filters: []*awsec2.Filter{
+			{
+				Name:   aws.String("tag:" + elbv2.ClusterTagKey(aws.StringValue(clusterName))),
+				Values: []*string{aws.String(elbv2.ClusterTagOwnedValue)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.SecurityGroups, nil
+}
@@ -0,0 +1,111 @@
+// Package elbv2 wraps the AWS SDK's elbv2 client with the handful of higher-level operations the
+// controller needs: discovering which load balancers it owns, and tagging new ones as such.
+package elbv2
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awselbv2 "github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+)
+
+// ClusterTagKeyPrefix, combined with a cluster name, forms the tag key the controller uses to
+// mark an ALB/NLB/target group as belonging to a given cluster. Mirrors the
+// kubernetes.io/cluster/<name> convention used by the in-tree AWS cloud provider for ELBs.
+const ClusterTagKeyPrefix = "kubernetes.io/cluster/"
+
+// ClusterTagOwnedValue is the value paired with the cluster tag key on resources the controller
+// manages (as opposed to "shared", which the upstream convention reserves for resources another
+// cluster also has a stake in; this controller never creates those).
+const ClusterTagOwnedValue = "owned"
+
+// ResourceTagKey records what kind of AWS resource a tagged object is, since a single cluster tag
+// doesn't distinguish a load balancer from a target group sharing the same ARN namespace.
+const ResourceTagKey = "ingress.k8s.aws/resource"
+
+// ELBV2 wraps the elbv2 SDK client, promoting its methods (DescribeRules, CreateRule, AddTags,
+// etc.) directly so callers can use it exactly like the raw client.
+type ELBV2 struct {
+	elbv2iface.ELBV2API
+}
+
+// ELBV2svc is the ELBV2 instance used throughout the controller, set by NewELBV2.
+var ELBV2svc *ELBV2
+
+// NewELBV2 sets ELBV2svc to a new client built from sess.
+func NewELBV2(sess *session.Session) {
+	ELBV2svc = &ELBV2{awselbv2.New(sess)}
+}
+
+// ClusterTagKey returns the tag key used to mark a resource as owned by clusterName.
+func ClusterTagKey(clusterName string) string {
+	return ClusterTagKeyPrefix + clusterName
+}
+
+// GetClusterLoadBalancers returns every ALB/NLB tagged as owned by clusterName. Tag filtering
+// isn't supported by DescribeLoadBalancers, so this describes everything in the account/region
+// and filters by tag afterward, in batches of 20 ARNs (the DescribeTags limit).
+func (e *ELBV2) GetClusterLoadBalancers(clusterName *string) ([]*awselbv2.LoadBalancer, error) {
+	var owned []*awselbv2.LoadBalancer
+	var all []*awselbv2.LoadBalancer
+
+	err := e.DescribeLoadBalancersPages(&awselbv2.DescribeLoadBalancersInput{},
+		func(page *awselbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			all = append(all, page.LoadBalancers...)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	tagKey := ClusterTagKey(aws.StringValue(clusterName))
+	for _, chunk := range chunkLoadBalancers(all, 20) {
+		arns := make([]*string, 0, len(chunk))
+		byArn := make(map[string]*awselbv2.LoadBalancer, len(chunk))
+		for _, lb := range chunk {
+			arns = append(arns, lb.LoadBalancerArn)
+			byArn[aws.StringValue(lb.LoadBalancerArn)] = lb
+		}
+
+		resp, err := e.DescribeTags(&awselbv2.DescribeTagsInput{ResourceArns: arns})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tagDescription := range resp.TagDescriptions {
+			for _, tag := range tagDescription.Tags {
+				if aws.StringValue(tag.Key) == tagKey && aws.StringValue(tag.Value) == ClusterTagOwnedValue {
+					owned = append(owned, byArn[aws.StringValue(tagDescription.ResourceArn)])
+					break
+				}
+			}
+		}
+	}
+
+	return owned, nil
+}
+
+// TagResource applies the cluster ownership and resource-type tags to arn; called once when the
+// controller creates a load balancer or target group.
+func (e *ELBV2) TagResource(arn *string, clusterName, resourceType string) error {
+	_, err := e.AddTags(&awselbv2.AddTagsInput{
+		ResourceArns: []*string{arn},
+		Tags: []*awselbv2.Tag{
+			{Key: aws.String(ClusterTagKey(clusterName)), Value: aws.String(ClusterTagOwnedValue)},
+			{Key: aws.String(ResourceTagKey), Value: aws.String(resourceType)},
+		},
+	})
+	return err
+}
+
+func chunkLoadBalancers(lbs []*awselbv2.LoadBalancer, size int) [][]*awselbv2.LoadBalancer {
+	var chunks [][]*awselbv2.LoadBalancer
+	for i := 0; i < len(lbs); i += size {
+		end := i + size
+		if end > len(lbs) {
+			end = len(lbs)
+		}
+		chunks = append(chunks, lbs[i:end])
+	}
+	return chunks
+}
@@ -0,0 +1,35 @@
+package elbv2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awselbv2 "github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+func TestClusterTagKey(t *testing.T) {
+	if got, want := ClusterTagKey("my-cluster"), "kubernetes.io/cluster/my-cluster"; got != want {
+		t.Errorf("ClusterTagKey() = %v, want %v", got, want)
+	}
+}
+
+func TestChunkLoadBalancers(t *testing.T) {
+	lbs := make([]*awselbv2.LoadBalancer, 45)
+	for i := range lbs {
+		lbs[i] = &awselbv2.LoadBalancer{LoadBalancerArn: aws.String(string(rune('a' + i)))}
+	}
+
+	chunks := chunkLoadBalancers(lbs, 20)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	if len(chunks[0]) != 20 || len(chunks[1]) != 20 || len(chunks[2]) != 5 {
+		t.Errorf("unexpected chunk sizes: %d, %d, %d", len(chunks[0]), len(chunks[1]), len(chunks[2]))
+	}
+}
+
+func TestChunkLoadBalancersEmpty(t *testing.T) {
+	if chunks := chunkLoadBalancers(nil, 20); len(chunks) != 0 {
+		t.Errorf("expected no chunks for empty input, got %d", len(chunks))
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"net/http"
 	"os"
 	"sort"
-	"strings"
+	"strconv"
 	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -15,12 +15,17 @@ import (
 
 	api "k8s.io/api/core/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/ingress/core/pkg/ingress"
 	"k8s.io/ingress/core/pkg/ingress/annotations/class"
 	"k8s.io/ingress/core/pkg/ingress/controller"
 	"k8s.io/ingress/core/pkg/ingress/defaults"
 
+	"github.com/coreos/alb-ingress-controller/pkg/alb/nlb"
+	"github.com/coreos/alb-ingress-controller/pkg/alb/rules"
 	"github.com/coreos/alb-ingress-controller/pkg/aws/acm"
 	"github.com/coreos/alb-ingress-controller/pkg/aws/ec2"
 	"github.com/coreos/alb-ingress-controller/pkg/aws/elbv2"
@@ -35,23 +40,65 @@ import (
 
 // ALBController is our main controller
 type ALBController struct {
-	storeLister  ingress.StoreLister
-	recorder     record.EventRecorder
-	ALBIngresses albingress.ALBIngressesT
-	clusterName  string
-	IngressClass string
+	storeLister     ingress.StoreLister
+	recorder        record.EventRecorder
+	kubeClient      kubernetes.Interface
+	ALBIngresses    albingress.ALBIngressesT
+	clusterName     string
+	clusterUID      string
+	IngressClass    string
+	finalizerRemove bool
 }
 
 var logger *log.Logger
 
+// groupNameAnnotation groups several Ingress resources onto a single shared ALB. All Ingresses
+// carrying the same group name contribute their listener rules to one ALBIngress/LoadBalancer;
+// groupOrderAnnotation controls where within that merged rule set an Ingress's own rules land.
+const (
+	groupNameAnnotation  = "alb.ingress.kubernetes.io/group.name"
+	groupOrderAnnotation = "alb.ingress.kubernetes.io/group.order"
+)
+
+// albCleanupFinalizer is added to every Ingress the controller manages so that the Kubernetes API
+// server blocks its deletion until the backing ALB, target groups, and security groups have
+// actually been torn down in AWS.
+const albCleanupFinalizer = "ingress.k8s.aws/alb-cleanup"
+
 func init() {
 	logger = log.New("controller")
 }
 
+// groupName returns the ALB group this ingress should share, or "" if the ingress is not part of
+// a group and should continue to own its ALB exclusively.
+func groupName(ingResource *extensions.Ingress) string {
+	return ingResource.Annotations[groupNameAnnotation]
+}
+
+// groupOrder returns the group.order annotation's value, defaulting to 0 (highest priority) when
+// absent or unparsable.
+func groupOrder(ingResource *extensions.Ingress) int {
+	order, err := strconv.Atoi(ingResource.Annotations[groupOrderAnnotation])
+	if err != nil {
+		return 0
+	}
+	return order
+}
+
+// sortGroupMembers orders the Ingress resources contributing to a shared ALB by their
+// group.order annotation so that rule priorities assigned further down the pipeline stay stable
+// across reconciles, regardless of the order the API server happened to return them in.
+func sortGroupMembers(members []*extensions.Ingress) {
+	sort.SliceStable(members, func(i, j int) bool {
+		return groupOrder(members[i]) < groupOrder(members[j])
+	})
+}
+
 // NewALBController returns an ALBController
 func NewALBController(awsconfig *aws.Config, conf *config.Config) *ALBController {
 	ac := new(ALBController)
 	sess := session.NewSession(awsconfig, conf.AWSDebug)
+	albprom.InstrumentAWSSession(sess)
 	elbv2.NewELBV2(sess)
 	ec2.NewEC2(sess)
 	acm.NewACM(sess)
@@ -66,19 +113,25 @@ func (ac *ALBController) Configure(ic *controller.GenericController) {
 		logger.Infof("Ingress class set to %s", ac.IngressClass)
 	}
 
-	if len(ac.clusterName) > 11 {
-		logger.Exitf("Cluster name must be 11 characters or less")
-	}
-
 	if ac.clusterName == "" {
 		logger.Exitf("A cluster name must be defined")
 	}
 
-	if strings.Contains(ac.clusterName, "-") {
-		logger.Exitf("Cluster name cannot contain '-'")
-	}
-
 	ac.recorder = ic.GetRecoder()
+	ac.kubeClient = ic.GetKubeClient()
+
+	// AWS resource names are derived from a short hash of this cluster UID plus the ALB group name
+	// (namespace/name for an Ingress with no group annotation),
+	// rather than the cluster name itself, so clusterName no longer needs to fit inside the
+	// character budget of an ELB/TG name and can contain hyphens. Ownership of a resource is
+	// determined separately, by the kubernetes.io/cluster/<clusterName>=owned tag applied at
+	// creation time (see pkg/aws/elbv2 and pkg/aws/ec2), so clusterName itself never has to change
+	// for existing resources to keep being recognized as ours.
+	kubeSystem, err := ac.kubeClient.CoreV1().Namespaces().Get("kube-system", metav1.GetOptions{})
+	if err != nil {
+		logger.Exitf("Failed to determine cluster UID from the kube-system namespace: %s", err.Error())
+	}
+	ac.clusterUID = string(kubeSystem.UID)
 }
 
 // OnUpdate is a callback invoked from the sync queue when ingress resources, or resources ingress
@@ -86,6 +139,10 @@ func (ac *ALBController) Configure(ic *controller.GenericController) {
 // against the existing ALBIngress list known to the ALBController. Eventually the state of this
 // list is synced resulting in new ingresses causing resource creation, modified ingresses having
 // resources modified (when appropriate) and ingresses missing from the new list deleted from AWS.
+// One ALBIngress is built per ALB group, not per Ingress: reconcileGroupRules gathers the merged,
+// priority-ordered listener rules from every valid member of a group and hands them to
+// pkg/alb/rules once the ALBIngress's listener exists, so Ingresses sharing a group.name don't
+// race to delete each other's rules.
 func (ac *ALBController) OnUpdate(_ ingress.Configuration) error {
 	albprom.OnUpdateCount.Add(float64(1))
 
@@ -93,20 +150,82 @@ func (ac *ALBController) OnUpdate(_ ingress.Configuration) error {
 
 	// Create new ALBIngress list for this invocation.
 	var ALBIngresses albingress.ALBIngressesT
-	// Find every ingress currently in Kubernetes.
+	// Find every ingress currently in Kubernetes, bucketed by the ALB group it contributes to.
+	// Ingresses without a group annotation are each their own single-member group, preserving the
+	// existing 1:1 Ingress-to-LoadBalancer behavior.
+	groups := make(map[string][]*extensions.Ingress)
+	var groupOrder []string
 	for _, ingress := range ac.storeLister.Ingress.List() {
 		ingResource := ingress.(*extensions.Ingress)
 		// Ensure the ingress resource found contains an appropriate ingress class.
 		if !class.IsValid(ingResource, ac.IngressClass, ac.DefaultIngressClass()) {
 			continue
 		}
-		// Produce a new ALBIngress instance for every ingress found. If ALBIngress returns nil, there
-		// was an issue with the ingress (e.g. bad annotations) and should not be added to the list.
+		name := groupName(ingResource)
+		if name == "" {
+			name = fmt.Sprintf("%s/%s", ingResource.Namespace, ingResource.Name)
+		}
+		if _, ok := groups[name]; !ok {
+			groupOrder = append(groupOrder, name)
+		}
+		groups[name] = append(groups[name], ingResource)
+	}
+
+	// Ingresses with a DeletionTimestamp are being deleted but are held back by our finalizer until
+	// we've confirmed their AWS resources are gone; track them so their finalizer can be stripped
+	// once Reconcile() reports the group's delete (or, for a member leaving a group that survives,
+	// the group's update) succeeded.
+	type pendingRemoval struct {
+		ingResource *extensions.Ingress
+		group       string
+	}
+	var pendingFinalizerRemoval []pendingRemoval
+
+	// groupMembers holds, per group, the members that actually went into this pass's
+	// NewALBIngressFromIngress call, so the reconcile loop below can find them again by GroupName
+	// to build that group's merged listener rules.
+	groupMembers := make(map[string][]*extensions.Ingress)
+
+	for _, name := range groupOrder {
+		members := groups[name]
+		sortGroupMembers(members)
+
+		var valid []*extensions.Ingress
+		for _, ingResource := range members {
+			if ingResource.DeletionTimestamp != nil {
+				// Deleting: don't run it through nlb.Validate, which would otherwise reject a
+				// misconfigured NLB Ingress every pass and leave its finalizer stripping code
+				// unreachable forever. It's not a candidate for this group's rules either way.
+				pendingFinalizerRemoval = append(pendingFinalizerRemoval, pendingRemoval{ingResource: ingResource, group: name})
+				continue
+			}
+			if err := ac.addFinalizer(ingResource); err != nil {
+				logger.Errorf("Failed to add finalizer to %s/%s: %s", ingResource.Namespace, ingResource.Name, err.Error())
+			}
+			if err := nlb.Validate(ingResource, ac.recorder); err != nil {
+				logger.Errorf("%s/%s: %s", ingResource.Namespace, ingResource.Name, err.Error())
+				continue
+			}
+			valid = append(valid, ingResource)
+		}
+
+		var lbType nlb.LoadBalancerType
+		if len(valid) > 0 {
+			lbType = nlb.TypeFor(valid[0])
+		}
+
+		// Produce a new ALBIngress for the group. If it returns nil, every member failed
+		// validation (or is being deleted) and there's nothing to build this pass; the group's
+		// existing ALB, if any, is picked up by ingressToDelete below instead.
 		ALBIngress, err := albingress.NewALBIngressFromIngress(&albingress.NewALBIngressFromIngressOptions{
-			Ingress:            ingResource,
+			Ingresses:          valid,
 			ExistingIngresses:  ac.ALBIngresses,
 			ClusterName:        ac.clusterName,
+			ClusterUID:         ac.clusterUID,
+			GroupName:          name,
+			LoadBalancerType:   string(lbType),
 			GetServiceNodePort: ac.GetServiceNodePort,
+			GetPodsForService:  ac.GetPodsForService,
 			GetNodes:           ac.GetNodes,
 			Recorder:           ac.recorder,
 		})
@@ -116,7 +235,7 @@ func (ac *ALBController) OnUpdate(_ ingress.Configuration) error {
 		if err != nil {
 			ALBIngress.Tainted = true
 		}
-		// Add the new ALBIngress instance to the new ALBIngress list.
+		groupMembers[name] = valid
 		ALBIngresses = append(ALBIngresses, ALBIngress)
 	}
 
@@ -133,20 +252,131 @@ func (ac *ALBController) OnUpdate(_ ingress.Configuration) error {
 	ac.ALBIngresses = ALBIngresses
 
 	// Sync the state, resulting in creation, modify, delete, or no action, for every ALBIngress
-	// instance known to the ALBIngress controller.
+	// instance known to the ALBIngress controller. reconcileErrs records, per group name, whether
+	// this pass's Reconcile() succeeded, so the finalizer-removal loop below can require a clean
+	// reconcile rather than just inferring deletion from the absence of a LoadBalancer.
 	var wg sync.WaitGroup
+	var reconcileErrsMu sync.Mutex
+	reconcileErrs := make(map[string]error, len(ac.ALBIngresses))
 	wg.Add(len(ac.ALBIngresses))
 	for _, ingress := range ac.ALBIngresses {
 		go func(wg *sync.WaitGroup, ingress *albingress.ALBIngress) {
 			defer wg.Done()
-			ingress.Reconcile(albingress.NewReconcileOptions().SetEventf(ingress.Eventf))
+			hadLoadBalancer := ingress.LoadBalancer != nil
+			err := ingress.Reconcile(albingress.NewReconcileOptions().SetEventf(ingress.Eventf))
+			if err == nil && ingress.LoadBalancer != nil {
+				if members, ok := groupMembers[ingress.GroupName]; ok {
+					if rerr := reconcileGroupRules(ingress, members); rerr != nil {
+						logger.Errorf("Failed to reconcile listener rules for group %s: %s", ingress.GroupName, rerr.Error())
+						err = rerr
+					}
+				}
+			}
+			// ALBIngress.Reconcile doesn't report back what it did, only whether it errored, so
+			// "created" and "deleted" are inferred from whether a LoadBalancer appeared or
+			// disappeared across the call. A pre-existing LoadBalancer that's still there is
+			// reported as "synced" rather than split into "modified"/"noop", since that split
+			// would need Reconcile itself to say whether it changed anything.
+			hasLoadBalancer := ingress.LoadBalancer != nil
+			result := "noop"
+			switch {
+			case err != nil:
+				result = "error"
+			case hadLoadBalancer && !hasLoadBalancer:
+				result = "deleted"
+			case !hadLoadBalancer && hasLoadBalancer:
+				result = "created"
+			case hasLoadBalancer:
+				result = "synced"
+			}
+			albprom.ReconcileResult(ingress.Namespace(), ingress.Name(), result)
+
+			reconcileErrsMu.Lock()
+			reconcileErrs[ingress.GroupName] = err
+			reconcileErrsMu.Unlock()
 		}(&wg, ingress)
 	}
 	wg.Wait()
 
+	// Now that Reconcile() has run, strip the finalizer from any Ingress pending removal whose
+	// group was actually reconciled this pass with no error. A group whose reconcile errored is
+	// left alone rather than having its members' finalizers silently stripped.
+	if ac.finalizerRemove {
+		for _, pending := range pendingFinalizerRemoval {
+			groupAlive := false
+			groupKnown := false
+			for _, albIng := range ALBIngresses {
+				if albIng.GroupName != pending.group {
+					continue
+				}
+				groupKnown = true
+				if albIng.LoadBalancer != nil {
+					groupAlive = true
+				}
+			}
+			if !groupKnown {
+				// This Ingress's group never made it into an ALBIngress at all this pass, or any
+				// previous one: nothing was ever created in AWS for it, so there's nothing to wait
+				// on Reconcile() for. Without this, an Ingress deleted before its first successful
+				// reconcile (e.g. one nlb.Validate would have rejected) would never see its group
+				// name in reconcileErrs and would hang forever waiting for a reconcile that will
+				// never run.
+				if err := ac.removeFinalizer(pending.ingResource); err != nil {
+					logger.Errorf("Failed to remove finalizer from %s/%s: %s", pending.ingResource.Namespace, pending.ingResource.Name, err.Error())
+				}
+				continue
+			}
+			if groupAlive {
+				continue
+			}
+			rerr, reconciled := reconcileErrs[pending.group]
+			if !reconciled || rerr != nil {
+				continue
+			}
+			if err := ac.removeFinalizer(pending.ingResource); err != nil {
+				logger.Errorf("Failed to remove finalizer from %s/%s: %s", pending.ingResource.Namespace, pending.ingResource.Name, err.Error())
+			}
+		}
+	}
+
 	return nil
 }
 
+// reconcileGroupRules builds the desired listener rules for every host/path declared across all
+// of a group's valid members, in group.order priority, one rule per path forwarding to the target
+// group backing that path's service, and reconciles them against the ALBIngress's listener as a
+// single merged set. It's a no-op for an ALBIngress whose listener isn't up yet (ListenerArn
+// returns nil), which Reconcile() will have created in this same pass.
+func reconcileGroupRules(albIng *albingress.ALBIngress, members []*extensions.Ingress) error {
+	listenerArn := albIng.ListenerArn()
+	if listenerArn == nil {
+		return nil
+	}
+
+	var desired rules.Rules
+	priority := 1
+	for _, ingResource := range members {
+		for _, rule := range ingResource.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				targetGroupArn := albIng.TargetGroupArnForBackend(path.Backend)
+				if targetGroupArn == nil {
+					continue
+				}
+				desired = append(desired, rules.NewRule(priority, rule.Host, path.Path, targetGroupArn))
+				priority++
+			}
+		}
+	}
+
+	if len(desired) == 0 {
+		return nil
+	}
+	return desired.Reconcile(listenerArn)
+}
+
 // OverrideFlags configures optional override flags for the ingress controller
 func (ac *ALBController) OverrideFlags(flags *pflag.FlagSet) {
 	flags.Set("update-status-on-shutdown", "false")
@@ -195,19 +425,13 @@ func (ac *ALBController) Info() *ingress.BackendInfo {
 // ConfigureFlags
 func (ac *ALBController) ConfigureFlags(pf *pflag.FlagSet) {
 	pf.StringVar(&ac.clusterName, "clusterName", os.Getenv("CLUSTER_NAME"), "Cluster Name (required)")
+	pf.BoolVar(&ac.finalizerRemove, "finalizer-remove", true, "Remove the alb-cleanup finalizer once an Ingress's AWS resources have been deleted. Disable during upgrades from a version that doesn't understand the finalizer.")
 }
 
 func (ac *ALBController) UpdateIngressStatus(ing *extensions.Ingress) []api.LoadBalancerIngress {
-	ingress := albingress.NewALBIngress(&albingress.NewALBIngressOptions{
-		Namespace:   ing.ObjectMeta.Namespace,
-		Name:        ing.ObjectMeta.Name,
-		ClusterName: ac.clusterName,
-		Recorder:    ac.recorder,
-	})
-
-	i := ac.ALBIngresses.Find(ingress)
+	i := ac.ALBIngresses.FindMember(ing.ObjectMeta.Namespace, ing.ObjectMeta.Name)
 	if i < 0 {
-		logger.Errorf("Unable to find ingress %s", ingress.Name())
+		logger.Errorf("Unable to find ingress %s/%s", ing.ObjectMeta.Namespace, ing.ObjectMeta.Name)
 		return nil
 	}
 
@@ -219,7 +443,10 @@ func (ac *ALBController) UpdateIngressStatus(ing *extensions.Ingress) []api.Load
 	return hostnames
 }
 
-// GetServiceNodePort returns the nodeport for a given Kubernetes service
+// GetServiceNodePort returns the nodeport for a given Kubernetes service. It backs the default
+// instance target-type, where the ALB target group registers node ExternalIDs (see GetNodes) and
+// traffic reaches pods via the node's NodePort. Services requesting target-type: ip instead
+// register pod IPs directly; see GetPodsForService.
 func (ac *ALBController) GetServiceNodePort(serviceKey string, backendPort int32) (*int64, error) {
 	// Verify the service (namespace/service-name) exists in Kubernetes.
 	item, exists, _ := ac.storeLister.Service.GetByKey(serviceKey)
@@ -243,12 +470,64 @@ func (ac *ALBController) GetServiceNodePort(serviceKey string, backendPort int32
 	return nil, fmt.Errorf("Unable to find a port defined in the %v service", serviceKey)
 }
 
+// GetPodsForService returns the ready pods backing a given Kubernetes service's port, for use by
+// the target-type: ip annotation. Unlike GetServiceNodePort, it works against ClusterIP services
+// as well as NodePort ones, since target registration goes straight to the pod IP over the VPC
+// CNI rather than through a node's NodePort.
+func (ac *ALBController) GetPodsForService(serviceKey string, backendPort int32) []*api.Pod {
+	var pods []*api.Pod
+
+	item, exists, _ := ac.storeLister.Endpoint.GetByKey(serviceKey)
+	if !exists {
+		return pods
+	}
+
+	for _, subset := range item.(*api.Endpoints).Subsets {
+		portMatches := false
+		for _, p := range subset.Ports {
+			if p.Port == int32(backendPort) {
+				portMatches = true
+				break
+			}
+		}
+		if !portMatches {
+			continue
+		}
+
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+			podKey := fmt.Sprintf("%s/%s", address.TargetRef.Namespace, address.TargetRef.Name)
+			podItem, exists, _ := ac.storeLister.Pod.GetByKey(podKey)
+			if !exists {
+				continue
+			}
+			pods = append(pods, podItem.(*api.Pod))
+		}
+	}
+
+	return pods
+}
+
 // Returns a list of ingress objects that are no longer known to kubernetes and should
-// be deleted.
+// be deleted. It covers both groups whose every member is being deleted behind the finalizer
+// (OnUpdate builds no new ALBIngress for them, so their ALB reaches Reconcile only through this
+// list) and Ingresses that disappear from the API server without going through the
+// DeletionTimestamp/finalizer handshake (e.g. an upgrade from a controller version that never set
+// the finalizer).
 // TODO: Move to ingress
 func (ac *ALBController) ingressToDelete(newList albingress.ALBIngressesT) albingress.ALBIngressesT {
 	var deleteableIngress albingress.ALBIngressesT
 
+	// Ingresses still present in newList are grouped here so a departing group member can be told
+	// whether siblings remain; the shared ALB is only a deletion candidate once the last member of
+	// its group has left.
+	remainingGroups := make(map[string]bool)
+	for _, ingress := range newList {
+		remainingGroups[ingress.GroupName] = true
+	}
+
 	// Loop through every ingress in current (old) ingress list known to ALBController
 	for _, ingress := range ac.ALBIngresses {
 		// If assembling the ingress resource failed, don't attempt deletion
@@ -257,19 +536,82 @@ func (ac *ALBController) ingressToDelete(newList albingress.ALBIngressesT) albin
 		}
 		// Ingress objects not found in newList might qualify for deletion.
 		if i := newList.Find(ingress); i < 0 {
-			// If the ALBIngress still contains a LoadBalancer, it still needs to be deleted.
-			// In this case, strip all desired state and add it to the deleteableIngress list.
-			// If the ALBIngress contains no LoadBalancer, it was previously deleted and is
-			// no longer relevant to the ALBController.
-			if ingress.LoadBalancer != nil {
-				ingress.StripDesiredState()
-				deleteableIngress = append(deleteableIngress, ingress)
+			if ingress.LoadBalancer == nil {
+				// The ALBIngress contains no LoadBalancer; it was previously deleted and is no
+				// longer relevant to the ALBController.
+				continue
+			}
+			// Strip all desired state and add it to the deleteableIngress list so Reconcile() runs
+			// for it on this pass, which tears down its whole ALB. A group that still has members
+			// in newList is matched by newList.Find on GroupName and never reaches this point, so
+			// a shared ALB is only deleted once its last member has left; a departing member's
+			// listener rules are dropped by reconcileGroupRules on the surviving group instead.
+			ingress.StripDesiredState()
+			deleteableIngress = append(deleteableIngress, ingress)
+			if ingress.GroupName != "" && remainingGroups[ingress.GroupName] {
+				logger.Debugf("%s/%s leaving group %s; other members remain, ALB will not be torn down", ingress.Namespace(), ingress.Name(), ingress.GroupName)
 			}
 		}
 	}
 	return deleteableIngress
 }
 
+// addFinalizer adds albCleanupFinalizer to ingResource if it isn't already present, so the API
+// server rejects deletion until we've removed it ourselves.
+func (ac *ALBController) addFinalizer(ingResource *extensions.Ingress) error {
+	if hasFinalizer(ingResource, albCleanupFinalizer) {
+		return nil
+	}
+	return ac.patchFinalizers(ingResource, append(append([]string{}, ingResource.Finalizers...), albCleanupFinalizer))
+}
+
+// removeFinalizer strips albCleanupFinalizer from ingResource, allowing the API server to finish
+// deleting it. OnUpdate calls it once the Ingress's group has been reconciled without error this
+// pass and no longer has a LoadBalancer, or straight away when no ALBIngress exists for that
+// group at all.
+func (ac *ALBController) removeFinalizer(ingResource *extensions.Ingress) error {
+	if !hasFinalizer(ingResource, albCleanupFinalizer) {
+		return nil
+	}
+	var finalizers []string
+	for _, f := range ingResource.Finalizers {
+		if f != albCleanupFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	return ac.patchFinalizers(ingResource, finalizers)
+}
+
+// patchFinalizers sends a JSON merge patch that replaces only metadata.finalizers (a strategic
+// merge patch would union the list with the current one and never drop an entry), rather than
+// Update()ing a DeepCopy of the object we got from the informer's lister cache: the lister's copy
+// can be stale by the time we write, and a blind Update would either conflict on resourceVersion
+// or clobber a concurrent status/spec write with our stale copy of the rest of the object.
+func (ac *ALBController) patchFinalizers(ingResource *extensions.Ingress, finalizers []string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers": finalizers,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ac.kubeClient.ExtensionsV1beta1().Ingresses(ingResource.Namespace).Patch(ingResource.Name, types.MergePatchType, patch)
+	return err
+}
+
+func hasFinalizer(ingResource *extensions.Ingress, finalizer string) bool {
+	for _, f := range ingResource.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// StateHandler is mounted alongside promhttp.Handler() (wrapping the registry pkg/prometheus
+// registers into) on the ingress controller's existing HTTP mux, so /state and /metrics are served
+// from the same port.
 func (ac *ALBController) StateHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(ac.ALBIngresses)
@@ -295,6 +637,7 @@ func (ac *ALBController) AssembleIngresses() {
 			albIngress, err := albingress.NewALBIngressFromAWSLoadBalancer(&albingress.NewALBIngressFromAWSLoadBalancerOptions{
 				LoadBalancer: loadBalancer,
 				ClusterName:  ac.clusterName,
+				ClusterUID:   ac.clusterUID,
 				Recorder:     ac.recorder,
 			})
 			if err != nil {
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/coreos/alb-ingress-controller/pkg/util/testutil"
+)
+
+func ingressNamed(name string, order string) *extensions.Ingress {
+	var annotations map[string]string
+	if order != "" {
+		annotations = map[string]string{groupOrderAnnotation: order}
+	}
+	return testutil.NewIngress(name, annotations)
+}
+
+func TestSortGroupMembersIsStableForTies(t *testing.T) {
+	members := []*extensions.Ingress{
+		ingressNamed("c", ""),
+		ingressNamed("a", ""),
+		ingressNamed("b", ""),
+	}
+
+	sortGroupMembers(members)
+
+	got := []string{members[0].Name, members[1].Name, members[2].Name}
+	want := []string{"c", "a", "b"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sortGroupMembers() with all-zero priorities reordered ties: got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSortGroupMembersOrdersByAnnotation(t *testing.T) {
+	members := []*extensions.Ingress{
+		ingressNamed("second", "2"),
+		ingressNamed("first", "1"),
+	}
+
+	sortGroupMembers(members)
+
+	if members[0].Name != "first" || members[1].Name != "second" {
+		t.Errorf("expected members sorted by group.order, got %s, %s", members[0].Name, members[1].Name)
+	}
+}
+
+func TestGroupOrderDefaultsToZero(t *testing.T) {
+	if got := groupOrder(ingressNamed("x", "")); got != 0 {
+		t.Errorf("groupOrder() with no annotation = %d, want 0", got)
+	}
+	if got := groupOrder(ingressNamed("x", "not-a-number")); got != 0 {
+		t.Errorf("groupOrder() with unparsable annotation = %d, want 0", got)
+	}
+}
+
+func TestHasFinalizer(t *testing.T) {
+	ing := &extensions.Ingress{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{"other", albCleanupFinalizer}}}
+	if !hasFinalizer(ing, albCleanupFinalizer) {
+		t.Error("expected hasFinalizer to find albCleanupFinalizer")
+	}
+	if hasFinalizer(ing, "missing") {
+		t.Error("expected hasFinalizer to not find an absent finalizer")
+	}
+}
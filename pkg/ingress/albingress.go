@@ -0,0 +1,614 @@
+// Package ingress holds ALBIngress, the in-memory record of one ALB and the Ingress resources
+// sharing it: which listener rules and target groups it needs, and which of those AWS resources
+// already exist. pkg/controller builds one ALBIngress per alb.ingress.kubernetes.io/group.name
+// (or, for an Ingress with no group annotation, a synthetic single-member group) and calls
+// Reconcile on it every OnUpdate pass.
+package ingress
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	awselbv2 "github.com/aws/aws-sdk-go/service/elbv2"
+
+	api "k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/coreos/alb-ingress-controller/pkg/alb/nlb"
+	"github.com/coreos/alb-ingress-controller/pkg/aws/ec2"
+	"github.com/coreos/alb-ingress-controller/pkg/aws/elbv2"
+	util "github.com/coreos/alb-ingress-controller/pkg/util/types"
+)
+
+// Annotations read directly by this package; the rest (group.name, group.order, load-balancer-type)
+// are parsed by pkg/controller and handed in through NewALBIngressFromIngressOptions.
+const (
+	schemeAnnotation         = "alb.ingress.kubernetes.io/scheme"
+	subnetsAnnotation        = "alb.ingress.kubernetes.io/subnets"
+	securityGroupsAnnotation = "alb.ingress.kubernetes.io/security-groups"
+	vpcIDAnnotation          = "alb.ingress.kubernetes.io/vpc-id"
+	targetTypeAnnotation     = "alb.ingress.kubernetes.io/target-type"
+	targetTypeIP             = "ip"
+)
+
+// member is the contribution one Ingress resource makes to a shared ALBIngress: its rules, and
+// enough of itself (the whole object) to record events and resolve service backends against.
+type member struct {
+	ingress *extensions.Ingress
+}
+
+// ALBIngress is the in-memory representation of the ALB serving one group's Ingress resources.
+type ALBIngress struct {
+	// Tainted marks an ALBIngress whose build hit an error; pkg/controller's ingressToDelete never
+	// schedules a tainted ALBIngress for deletion. Reconcile itself does not check it.
+	Tainted bool
+	// LoadBalancer is the AWS load balancer backing this ALBIngress, nil before it's created or
+	// after it's been torn down.
+	LoadBalancer *awselbv2.LoadBalancer
+	// GroupName is the group.name annotation value shared by every member, or the synthetic
+	// "namespace/name" group a lone, unannotated Ingress gets. It's what ALBIngressesT.Find uses
+	// to recognize the same ALB across OnUpdate passes.
+	GroupName string
+
+	clusterName      string
+	clusterUID       string
+	loadBalancerType string
+	recorder         record.EventRecorder
+	members          []member
+
+	listenerArn     *string
+	targetGroupArns map[string]*string
+
+	// securityGroupID is set only when createLoadBalancer had to auto-create a security group
+	// because the Ingress didn't supply one via securityGroupsAnnotation; reconcileDelete only
+	// tears down a security group this ALBIngress actually owns, never one the annotation pointed
+	// at but didn't create.
+	securityGroupID *string
+
+	stripDesiredState bool
+
+	getServiceNodePort func(string, int32) (*int64, error)
+	getPodsForService  func(string, int32) []*api.Pod
+	getNodes           func() util.AWSStringSlice
+}
+
+// ALBIngressesT is a list of ALBIngresses, one per ALB the controller knows about.
+type ALBIngressesT []*ALBIngress
+
+// Find returns the index of the ALBIngress in list that is "the same" ALB as target: it shares
+// target's GroupName, or at least one member Ingress. A group's membership can shift slightly
+// (one Ingress leaving, another joining) between OnUpdate passes without losing track of the ALB.
+func (list ALBIngressesT) Find(target *ALBIngress) int {
+	for i, existing := range list {
+		if existing.GroupName != "" && existing.GroupName == target.GroupName {
+			return i
+		}
+		for _, m := range target.members {
+			if existing.hasMember(m.ingress.Namespace, m.ingress.Name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// FindMember returns the index of the ALBIngress in list whose members include the Ingress
+// identified by namespace/name, or -1 if none does. UpdateIngressStatus uses this to go from a
+// single Ingress object back to the (possibly shared) ALBIngress it contributes to.
+func (list ALBIngressesT) FindMember(namespace, name string) int {
+	for i, existing := range list {
+		if existing.hasMember(namespace, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *ALBIngress) hasMember(namespace, name string) bool {
+	for _, m := range a.members {
+		if m.ingress.Namespace == namespace && m.ingress.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewALBIngressFromIngressOptions configures NewALBIngressFromIngress. Ingresses holds every
+// member contributing to this group's shared ALB, already sorted (by pkg/controller, via the
+// group.order annotation) in the order their rules should take priority in.
+type NewALBIngressFromIngressOptions struct {
+	Ingresses          []*extensions.Ingress
+	ExistingIngresses  ALBIngressesT
+	ClusterName        string
+	ClusterUID         string
+	GroupName          string
+	LoadBalancerType   string
+	GetServiceNodePort func(string, int32) (*int64, error)
+	GetPodsForService  func(string, int32) []*api.Pod
+	GetNodes           func() util.AWSStringSlice
+	Recorder           record.EventRecorder
+}
+
+// NewALBIngressFromIngress builds the ALBIngress serving one group's Ingress resources, carrying
+// forward the listener/target-group ARNs a previous pass already created for the same group (so
+// Reconcile only creates what's missing). It returns nil when o.Ingresses is empty: there's
+// nothing for this group to build this pass (e.g. every member failed validation).
+func NewALBIngressFromIngress(o *NewALBIngressFromIngressOptions) (*ALBIngress, error) {
+	if len(o.Ingresses) == 0 {
+		return nil, nil
+	}
+
+	a := &ALBIngress{
+		GroupName:          o.GroupName,
+		clusterName:        o.ClusterName,
+		clusterUID:         o.ClusterUID,
+		loadBalancerType:   o.LoadBalancerType,
+		recorder:           o.Recorder,
+		getServiceNodePort: o.GetServiceNodePort,
+		getPodsForService:  o.GetPodsForService,
+		getNodes:           o.GetNodes,
+		targetGroupArns:    make(map[string]*string),
+	}
+	for _, ingResource := range o.Ingresses {
+		a.members = append(a.members, member{ingress: ingResource})
+	}
+
+	if i := o.ExistingIngresses.Find(a); i >= 0 {
+		existing := o.ExistingIngresses[i]
+		a.LoadBalancer = existing.LoadBalancer
+		a.listenerArn = existing.listenerArn
+		a.securityGroupID = existing.securityGroupID
+		for key, arn := range existing.targetGroupArns {
+			a.targetGroupArns[key] = arn
+		}
+	}
+
+	return a, nil
+}
+
+// NewALBIngressFromAWSLoadBalancerOptions configures NewALBIngressFromAWSLoadBalancer.
+type NewALBIngressFromAWSLoadBalancerOptions struct {
+	LoadBalancer *awselbv2.LoadBalancer
+	ClusterName  string
+	ClusterUID   string
+	Recorder     record.EventRecorder
+}
+
+// NewALBIngressFromAWSLoadBalancer rebuilds a bare ALBIngress record for a LoadBalancer the
+// controller already owns (found via its cluster ownership tag) but has no in-memory state for,
+// e.g. right after the controller restarts. It carries no members and no GroupName yet: the next
+// OnUpdate pass reattaches it to its group via ALBIngressesT.Find once that group's Ingresses are
+// read back from the API server, matching it up by shared members rather than by name.
+func NewALBIngressFromAWSLoadBalancer(o *NewALBIngressFromAWSLoadBalancerOptions) (*ALBIngress, error) {
+	return &ALBIngress{
+		LoadBalancer: o.LoadBalancer,
+		clusterName:  o.ClusterName,
+		clusterUID:   o.ClusterUID,
+		recorder:     o.Recorder,
+	}, nil
+}
+
+// StripDesiredState marks this ALBIngress for deletion: the next Reconcile call tears down its
+// LoadBalancer and everything under it instead of reconciling it toward its members' rules.
+func (a *ALBIngress) StripDesiredState() {
+	a.stripDesiredState = true
+}
+
+// Namespace returns the namespace of this ALBIngress's lead (first) member. For a shared ALB this
+// is only one of potentially several member namespaces; it exists for logging and metric labels,
+// not to enumerate every Ingress behind the ALB.
+func (a *ALBIngress) Namespace() string {
+	if len(a.members) == 0 {
+		return ""
+	}
+	return a.members[0].ingress.Namespace
+}
+
+// Name returns the name of this ALBIngress's lead (first) member, for the same reason Namespace
+// does: logging and metric labels, not group membership.
+func (a *ALBIngress) Name() string {
+	if len(a.members) == 0 {
+		return ""
+	}
+	return a.members[0].ingress.Name
+}
+
+// Eventf records an event against every Ingress sharing this ALBIngress, since there's no single
+// Kubernetes object a shared ALB's events naturally belong to.
+func (a *ALBIngress) Eventf(eventtype, reason, messageFmt string, args ...interface{}) {
+	if a.recorder == nil {
+		return
+	}
+	for _, m := range a.members {
+		a.recorder.Eventf(m.ingress, eventtype, reason, messageFmt, args...)
+	}
+}
+
+// Hostnames returns the LoadBalancerIngress status entries to publish on every Ingress sharing
+// this ALBIngress.
+func (a *ALBIngress) Hostnames() ([]api.LoadBalancerIngress, error) {
+	if a.LoadBalancer == nil || a.LoadBalancer.DNSName == nil {
+		return nil, fmt.Errorf("no load balancer DNS name available for %s", a.GroupName)
+	}
+	return []api.LoadBalancerIngress{{Hostname: aws.StringValue(a.LoadBalancer.DNSName)}}, nil
+}
+
+// ListenerArn returns this ALBIngress's listener ARN once Reconcile has created it, or nil before
+// that (or after StripDesiredState's next Reconcile has torn it down).
+func (a *ALBIngress) ListenerArn() *string {
+	return a.listenerArn
+}
+
+// TargetGroupArnForBackend returns the ARN of the target group serving backend's
+// (serviceName, servicePort), or nil if Reconcile hasn't created one for it yet.
+func (a *ALBIngress) TargetGroupArnForBackend(backend extensions.IngressBackend) *string {
+	return a.targetGroupArns[backendKey(backend)]
+}
+
+func backendKey(backend extensions.IngressBackend) string {
+	return backend.ServiceName + ":" + backend.ServicePort.String()
+}
+
+// ReconcileOptions carries per-call overrides for Reconcile; currently just the event callback
+// used to surface progress on the Ingress resources sharing this ALBIngress.
+type ReconcileOptions struct {
+	eventf func(eventtype, reason, messageFmt string, args ...interface{})
+}
+
+// NewReconcileOptions returns an empty ReconcileOptions for the caller to customize with SetEventf.
+func NewReconcileOptions() *ReconcileOptions {
+	return &ReconcileOptions{}
+}
+
+// SetEventf sets the function Reconcile calls to record progress events, returning ro so calls
+// chain the way ALBController.OnUpdate does: NewReconcileOptions().SetEventf(ingress.Eventf).
+func (ro *ReconcileOptions) SetEventf(eventf func(string, string, string, ...interface{})) *ReconcileOptions {
+	ro.eventf = eventf
+	return ro
+}
+
+func (ro *ReconcileOptions) recordEventf(eventtype, reason, messageFmt string, args ...interface{}) {
+	if ro == nil || ro.eventfFunc() == nil {
+		return
+	}
+	ro.eventfFunc()(eventtype, reason, messageFmt, args...)
+}
+
+func (ro *ReconcileOptions) eventfFunc() func(string, string, string, ...interface{}) {
+	if ro == nil {
+		return nil
+	}
+	return ro.eventf
+}
+
+// Reconcile syncs this ALBIngress toward everything its members' rules declare it needs: a
+// LoadBalancer, a listener, and one target group per unique backend referenced by any member's
+// rules (or, if StripDesiredState has been called, tears all of that down instead). Listener
+// rules themselves are reconciled separately by pkg/controller's reconcileGroupRules, once
+// Reconcile has returned a non-nil ListenerArn for pkg/alb/rules to target.
+func (a *ALBIngress) Reconcile(rOpts *ReconcileOptions) error {
+	if a.stripDesiredState || len(a.members) == 0 {
+		return a.reconcileDelete(rOpts)
+	}
+	return a.reconcileCreate(rOpts)
+}
+
+func (a *ALBIngress) reconcileCreate(rOpts *ReconcileOptions) error {
+	if a.loadBalancerType == string(nlb.TypeNLB) {
+		return fmt.Errorf("load-balancer-type=%s is not provisioned by this controller yet; refusing to fall back to an ALB", nlb.TypeNLB)
+	}
+
+	lead := a.members[0].ingress
+
+	if a.LoadBalancer == nil {
+		if err := a.createLoadBalancer(lead, rOpts); err != nil {
+			return err
+		}
+	}
+
+	var defaultTargetGroupArn *string
+	for _, m := range a.members {
+		for _, rule := range m.ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				tgArn, err := a.ensureTargetGroup(m.ingress, path.Backend)
+				if err != nil {
+					return err
+				}
+				if defaultTargetGroupArn == nil {
+					defaultTargetGroupArn = tgArn
+				}
+			}
+		}
+	}
+	if defaultTargetGroupArn == nil {
+		return fmt.Errorf("%s has no host/path rules to build a listener from", a.GroupName)
+	}
+
+	if a.listenerArn == nil {
+		if err := a.createListener(defaultTargetGroupArn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *ALBIngress) createLoadBalancer(lead *extensions.Ingress, rOpts *ReconcileOptions) error {
+	subnets := splitAnnotation(lead.Annotations[subnetsAnnotation])
+	if len(subnets) == 0 {
+		return fmt.Errorf("%s annotation is required to create a load balancer", subnetsAnnotation)
+	}
+	scheme := lead.Annotations[schemeAnnotation]
+	if scheme == "" {
+		scheme = "internal"
+	}
+
+	securityGroups := splitAnnotation(lead.Annotations[securityGroupsAnnotation])
+	if len(securityGroups) == 0 {
+		sgID, err := a.createSecurityGroup(lead)
+		if err != nil {
+			return fmt.Errorf("failed to create security group for %s: %s", a.GroupName, err.Error())
+		}
+		a.securityGroupID = sgID
+		securityGroups = []string{aws.StringValue(sgID)}
+	}
+
+	out, err := elbv2.ELBV2svc.CreateLoadBalancer(&awselbv2.CreateLoadBalancerInput{
+		Name:           aws.String(lbName(a.clusterUID, a.GroupName)),
+		Subnets:        aws.StringSlice(subnets),
+		Scheme:         aws.String(scheme),
+		SecurityGroups: aws.StringSlice(securityGroups),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create load balancer for %s: %s", a.GroupName, err.Error())
+	}
+	if len(out.LoadBalancers) == 0 {
+		return fmt.Errorf("CreateLoadBalancer for %s returned no load balancers", a.GroupName)
+	}
+	a.LoadBalancer = out.LoadBalancers[0]
+	if err := elbv2.ELBV2svc.TagResource(a.LoadBalancer.LoadBalancerArn, a.clusterName, "loadbalancer"); err != nil {
+		return fmt.Errorf("failed to tag load balancer %s: %s", aws.StringValue(a.LoadBalancer.LoadBalancerArn), err.Error())
+	}
+	rOpts.recordEventf("Normal", "CREATE", "created load balancer %s", aws.StringValue(a.LoadBalancer.LoadBalancerArn))
+	return nil
+}
+
+// createSecurityGroup creates a security group allowing inbound traffic on the load balancer's
+// listener port from anywhere, for Ingresses that don't supply their own via
+// securityGroupsAnnotation. It's tagged the same way as the load balancer and target groups it's
+// created alongside, so GetClusterSecurityGroups can find it again after a controller restart.
+func (a *ALBIngress) createSecurityGroup(lead *extensions.Ingress) (*string, error) {
+	vpcID := lead.Annotations[vpcIDAnnotation]
+	if vpcID == "" {
+		return nil, fmt.Errorf("%s annotation is required to auto-create a security group", vpcIDAnnotation)
+	}
+
+	out, err := ec2.EC2svc.CreateSecurityGroup(&awsec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(lbName(a.clusterUID, a.GroupName)),
+		Description: aws.String("managed by alb-ingress-controller for " + a.GroupName),
+		VpcId:       aws.String(vpcID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ec2.EC2svc.AuthorizeSecurityGroupIngress(&awsec2.AuthorizeSecurityGroupIngressInput{
+		GroupId: out.GroupId,
+		IpPermissions: []*awsec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(80),
+				ToPort:     aws.Int64(80),
+				IpRanges:   []*awsec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := ec2.EC2svc.TagSecurityGroup(out.GroupId, a.clusterName, "securitygroup"); err != nil {
+		return nil, err
+	}
+
+	return out.GroupId, nil
+}
+
+func (a *ALBIngress) ensureTargetGroup(ingResource *extensions.Ingress, backend extensions.IngressBackend) (*string, error) {
+	key := backendKey(backend)
+	if arn, ok := a.targetGroupArns[key]; ok {
+		return arn, nil
+	}
+
+	port, err := a.resolveBackendPort(ingResource, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := elbv2.ELBV2svc.CreateTargetGroup(&awselbv2.CreateTargetGroupInput{
+		Name:       aws.String(tgName(a.clusterUID, a.GroupName, key)),
+		Protocol:   aws.String("HTTP"),
+		Port:       port,
+		VpcId:      aws.String(ingResource.Annotations[vpcIDAnnotation]),
+		TargetType: aws.String(targetTypeFor(ingResource)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create target group for %s: %s", key, err.Error())
+	}
+	if len(out.TargetGroups) == 0 {
+		return nil, fmt.Errorf("CreateTargetGroup for %s returned no target groups", key)
+	}
+	tgArn := out.TargetGroups[0].TargetGroupArn
+	if err := elbv2.ELBV2svc.TagResource(tgArn, a.clusterName, "targetgroup"); err != nil {
+		return nil, fmt.Errorf("failed to tag target group %s: %s", aws.StringValue(tgArn), err.Error())
+	}
+	if err := a.registerTargets(ingResource, backend, tgArn); err != nil {
+		return nil, err
+	}
+
+	a.targetGroupArns[key] = tgArn
+	return tgArn, nil
+}
+
+func targetTypeFor(ingResource *extensions.Ingress) string {
+	if ingResource.Annotations[targetTypeAnnotation] == targetTypeIP {
+		return targetTypeIP
+	}
+	return "instance"
+}
+
+// resolveBackendPort returns the port target registration should use for backend: the service's
+// own port for target-type=ip (pod IPs are routable at the container port directly over the VPC
+// CNI), or the service's assigned NodePort for the default instance target type.
+func (a *ALBIngress) resolveBackendPort(ingResource *extensions.Ingress, backend extensions.IngressBackend) (*int64, error) {
+	if targetTypeFor(ingResource) == targetTypeIP {
+		port, err := strconv.ParseInt(backend.ServicePort.String(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("target-type=ip requires a numeric servicePort, got %q", backend.ServicePort.String())
+		}
+		return aws.Int64(port), nil
+	}
+	if a.getServiceNodePort == nil {
+		return nil, fmt.Errorf("no service node port resolver configured")
+	}
+	serviceKey := ingResource.Namespace + "/" + backend.ServiceName
+	servicePort, err := strconv.ParseInt(backend.ServicePort.String(), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid servicePort %q for %s: %s", backend.ServicePort.String(), serviceKey, err.Error())
+	}
+	return a.getServiceNodePort(serviceKey, int32(servicePort))
+}
+
+// registerTargets registers either the backend service's pods (target-type=ip) or every cluster
+// node (the default, instance target type) against tgArn.
+func (a *ALBIngress) registerTargets(ingResource *extensions.Ingress, backend extensions.IngressBackend, tgArn *string) error {
+	var targets []*awselbv2.TargetDescription
+
+	if targetTypeFor(ingResource) == targetTypeIP {
+		if a.getPodsForService == nil {
+			return fmt.Errorf("target-type=ip requested but no pod lister is configured")
+		}
+		port, err := strconv.ParseInt(backend.ServicePort.String(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("target-type=ip requires a numeric servicePort, got %q", backend.ServicePort.String())
+		}
+		serviceKey := ingResource.Namespace + "/" + backend.ServiceName
+		for _, pod := range a.getPodsForService(serviceKey, int32(port)) {
+			if pod.Status.PodIP == "" {
+				continue
+			}
+			targets = append(targets, &awselbv2.TargetDescription{
+				Id:   aws.String(pod.Status.PodIP),
+				Port: aws.Int64(port),
+			})
+		}
+	} else {
+		if a.getNodes == nil {
+			return fmt.Errorf("no node lister configured")
+		}
+		for _, id := range a.getNodes() {
+			targets = append(targets, &awselbv2.TargetDescription{Id: id})
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+	_, err := elbv2.ELBV2svc.RegisterTargets(&awselbv2.RegisterTargetsInput{
+		TargetGroupArn: tgArn,
+		Targets:        targets,
+	})
+	return err
+}
+
+func (a *ALBIngress) createListener(defaultTargetGroupArn *string) error {
+	out, err := elbv2.ELBV2svc.CreateListener(&awselbv2.CreateListenerInput{
+		LoadBalancerArn: a.LoadBalancer.LoadBalancerArn,
+		Protocol:        aws.String("HTTP"),
+		Port:            aws.Int64(80),
+		DefaultActions: []*awselbv2.Action{
+			{Type: aws.String("forward"), TargetGroupArn: defaultTargetGroupArn},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create listener for %s: %s", a.GroupName, err.Error())
+	}
+	if len(out.Listeners) == 0 {
+		return fmt.Errorf("CreateListener for %s returned no listeners", a.GroupName)
+	}
+	a.listenerArn = out.Listeners[0].ListenerArn
+	return nil
+}
+
+func (a *ALBIngress) reconcileDelete(rOpts *ReconcileOptions) error {
+	if a.LoadBalancer == nil {
+		return nil
+	}
+
+	if a.listenerArn != nil {
+		if _, err := elbv2.ELBV2svc.DeleteListener(&awselbv2.DeleteListenerInput{ListenerArn: a.listenerArn}); err != nil {
+			return fmt.Errorf("failed to delete listener for %s: %s", a.GroupName, err.Error())
+		}
+		a.listenerArn = nil
+	}
+	for key, tgArn := range a.targetGroupArns {
+		if _, err := elbv2.ELBV2svc.DeleteTargetGroup(&awselbv2.DeleteTargetGroupInput{TargetGroupArn: tgArn}); err != nil {
+			return fmt.Errorf("failed to delete target group %s: %s", key, err.Error())
+		}
+		delete(a.targetGroupArns, key)
+	}
+	if _, err := elbv2.ELBV2svc.DeleteLoadBalancer(&awselbv2.DeleteLoadBalancerInput{LoadBalancerArn: a.LoadBalancer.LoadBalancerArn}); err != nil {
+		return fmt.Errorf("failed to delete load balancer for %s: %s", a.GroupName, err.Error())
+	}
+	if a.securityGroupID != nil {
+		if _, err := ec2.EC2svc.DeleteSecurityGroup(&awsec2.DeleteSecurityGroupInput{GroupId: a.securityGroupID}); err != nil {
+			return fmt.Errorf("failed to delete security group %s for %s: %s", aws.StringValue(a.securityGroupID), a.GroupName, err.Error())
+		}
+		a.securityGroupID = nil
+	}
+	rOpts.recordEventf("Normal", "DELETE", "deleted load balancer %s", aws.StringValue(a.LoadBalancer.LoadBalancerArn))
+	a.LoadBalancer = nil
+	return nil
+}
+
+func splitAnnotation(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// resourceHash returns a hex-encoded hash of parts, used by lbName/tgName to derive AWS resource
+// names short enough to fit the ALB/target-group name limit.
+func resourceHash(parts ...string) string {
+	h := md5.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lbName derives a load balancer's name; ALB names are capped at 32 characters.
+func lbName(clusterUID, groupName string) string {
+	return "alb-" + resourceHash(clusterUID, groupName)[:28]
+}
+
+// tgName derives a target group's name; target group names are capped at 32 characters.
+func tgName(clusterUID, groupName, backendKey string) string {
+	return "tg-" + resourceHash(clusterUID, groupName, backendKey)[:29]
+}
@@ -0,0 +1,94 @@
+// Package prometheus holds the metrics the ALB ingress controller exposes and the instrumentation
+// used to populate them.
+package prometheus
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awssession "github.com/aws/aws-sdk-go/aws/session"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// OnUpdateCount counts the number of times the controller's sync queue has invoked OnUpdate.
+	OnUpdateCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "alb_ingress_controller_on_update_count",
+		Help: "Number of times OnUpdate has been called.",
+	})
+
+	// ManagedIngresses is the number of ALBIngresses currently known to the controller.
+	ManagedIngresses = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "alb_ingress_controller_managed_ingresses",
+		Help: "Number of ALBIngresses currently being managed by the controller.",
+	})
+
+	// AWSAPICallDuration tracks how long each AWS API call takes, broken down by the SDK's service
+	// name (e.g. elasticloadbalancing for elbv2, ec2, acm, iam) and operation (e.g.
+	// CreateLoadBalancer).
+	AWSAPICallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "aws_api_call_duration_seconds",
+		Help: "Duration of AWS API calls made by the controller.",
+	}, []string{"service", "operation"})
+
+	// AWSAPIThrottled counts AWS API calls that came back throttled, broken down the same way as
+	// AWSAPICallDuration.
+	AWSAPIThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "aws_api_throttled_total",
+		Help: "Number of AWS API calls that were throttled.",
+	}, []string{"service", "operation"})
+
+	// ReconcileCount counts the outcome of each ALBIngress's Reconcile call. result is one of
+	// "created", "synced", "deleted", "noop", or "error". "synced" covers both a no-op reconcile
+	// and one that modified an existing LoadBalancer's resources: Reconcile doesn't report back
+	// which of the two happened, only whether a LoadBalancer came into or out of existence.
+	ReconcileCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alb_reconcile_total",
+		Help: "Number of ALBIngress reconciles, broken down by outcome (created, synced, deleted, noop, error).",
+	}, []string{"result"})
+
+	// LastReconcileSuccess is the unix timestamp of the last successful reconcile for a given
+	// Ingress. Graphing time() - alb_last_reconcile_success_timestamp_seconds gives the
+	// time-since-last-successful-reconcile for that Ingress.
+	LastReconcileSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "alb_last_reconcile_success_timestamp_seconds",
+		Help: "Unix timestamp of the last successful reconcile for an Ingress.",
+	}, []string{"namespace", "ingress"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		OnUpdateCount,
+		ManagedIngresses,
+		AWSAPICallDuration,
+		AWSAPIThrottled,
+		ReconcileCount,
+		LastReconcileSuccess,
+	)
+}
+
+// ReconcileResult records the outcome of one ALBIngress reconcile and, on success, refreshes that
+// Ingress's LastReconcileSuccess timestamp.
+func ReconcileResult(namespace, name, result string) {
+	ReconcileCount.WithLabelValues(result).Inc()
+	if result != "error" {
+		LastReconcileSuccess.WithLabelValues(namespace, name).Set(float64(time.Now().Unix()))
+	}
+}
+
+// InstrumentAWSSession records AWSAPICallDuration and AWSAPIThrottled for every call made through
+// sess, labeled by service and operation name.
+func InstrumentAWSSession(sess *awssession.Session) {
+	sess.Handlers.Complete.PushBack(func(r *request.Request) {
+		service := r.ClientInfo.ServiceName
+		AWSAPICallDuration.WithLabelValues(service, r.Operation.Name).Observe(time.Since(r.Time).Seconds())
+
+		if r.Error == nil {
+			return
+		}
+		if awsErr, ok := r.Error.(awserr.Error); ok && (awsErr.Code() == "Throttling" || awsErr.Code() == "RequestLimitExceeded") {
+			AWSAPIThrottled.WithLabelValues(service, r.Operation.Name).Inc()
+		}
+	})
+}
@@ -0,0 +1,15 @@
+// Package testutil holds small Ingress fixture constructors shared across this tree's unit tests,
+// so each package's test file doesn't redefine its own one-off builder for the same bare Ingress
+// shape.
+package testutil
+
+import (
+	extensions "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewIngress returns a bare Ingress named name and carrying annotations. Tests that need more
+// (rules, a namespace, a DeletionTimestamp) set those fields on the result directly.
+func NewIngress(name string, annotations map[string]string) *extensions.Ingress {
+	return &extensions.Ingress{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+}